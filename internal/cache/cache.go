@@ -0,0 +1,233 @@
+// Package cache provides a small, generic, concurrency-safe TTL cache.
+package cache
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+)
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// ErrLoadPanicked is returned to callers waiting on a GetOrLoad whose load
+// function panicked.
+var ErrLoadPanicked = errors.New("cache: load panicked")
+
+// Stats is a point-in-time snapshot of a cache's counters.
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Size      int
+}
+
+// Cache is a TTL cache with an optional upper bound on the number of
+// entries. Every entry shares the same TTL, so entries are kept in insertion
+// order and the oldest one is evicted when the cache is full.
+// The zero value is not usable; create one with New.
+type Cache[K comparable, V any] struct {
+	mu         sync.Mutex
+	items      map[K]*list.Element
+	order      *list.List
+	loads      map[K]*call[V]
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+// New returns a cache whose entries expire after ttl. A ttl of 0 or less
+// means entries never expire and only leave the cache through eviction,
+// Delete or Clear. A maxEntries of 0 or less means the cache is unbounded;
+// expired entries are still removed as new ones are stored.
+func New[K comparable, V any](ttl time.Duration, maxEntries int) *Cache[K, V] {
+	return &Cache[K, V]{
+		items:      make(map[K]*list.Element),
+		order:      list.New(),
+		loads:      make(map[K]*call[V]),
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+	}
+}
+
+// Get returns the value stored for key, if present and not expired.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.getLocked(key, c.now())
+}
+
+// Set stores value for key, replacing any existing entry.
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.setLocked(key, value, c.now())
+}
+
+// GetOrLoad returns the cached value for key, calling load to populate the
+// cache on a miss. Concurrent callers that miss on the same key share a
+// single call to load. Errors from load are returned to every waiting caller
+// and are not cached. A Delete or Clear while load is running discards its
+// result instead of caching it, so invalidation is never undone by a load that
+// read the old data.
+func (c *Cache[K, V]) GetOrLoad(key K, load func() (V, error)) (V, error) {
+	c.mu.Lock()
+
+	if v, ok := c.getLocked(key, c.now()); ok {
+		c.mu.Unlock()
+		return v, nil
+	}
+
+	if inflight, ok := c.loads[key]; ok {
+		c.mu.Unlock()
+		<-inflight.done
+		return inflight.value, inflight.err
+	}
+
+	cl := &call[V]{done: make(chan struct{})}
+	c.loads[key] = cl
+	c.mu.Unlock()
+
+	finished := false
+
+	defer func() {
+		if !finished {
+			cl.err = ErrLoadPanicked
+		}
+
+		c.mu.Lock()
+		if c.loads[key] == cl {
+			delete(c.loads, key)
+			if cl.err == nil {
+				c.setLocked(key, cl.value, c.now())
+			}
+		}
+		c.mu.Unlock()
+
+		close(cl.done)
+	}()
+
+	cl.value, cl.err = load()
+	finished = true
+
+	return cl.value, cl.err
+}
+
+// Delete removes key from the cache and stops any in-flight GetOrLoad for
+// key from storing its result.
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.loads, key)
+
+	if el, ok := c.items[key]; ok {
+		c.removeLocked(el)
+	}
+}
+
+// Clear removes every entry from the cache and stops in-flight GetOrLoad
+// calls from storing their results. Counters are left untouched.
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[K]*list.Element)
+	c.order.Init()
+	c.loads = make(map[K]*call[V])
+}
+
+// Stats returns the cache's hit, miss and eviction counters and the number
+// of live entries.
+func (c *Cache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pruneLocked(c.now())
+
+	return Stats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Size:      len(c.items),
+	}
+}
+
+func (c *Cache[K, V]) getLocked(key K, now time.Time) (V, bool) {
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[K, V])
+		if !c.expired(e, now) {
+			c.hits++
+			return e.value, true
+		}
+
+		c.removeLocked(el)
+	}
+
+	c.misses++
+
+	var zero V
+	return zero, false
+}
+
+func (c *Cache[K, V]) setLocked(key K, value V, now time.Time) {
+	c.pruneLocked(now)
+
+	if el, ok := c.items[key]; ok {
+		c.removeLocked(el)
+	}
+
+	if c.maxEntries > 0 && len(c.items) >= c.maxEntries {
+		c.removeLocked(c.order.Front())
+		c.evictions++
+	}
+
+	e := &entry[K, V]{
+		key:   key,
+		value: value,
+	}
+
+	if c.ttl > 0 {
+		e.expiresAt = now.Add(c.ttl)
+	}
+
+	c.items[key] = c.order.PushBack(e)
+}
+
+// pruneLocked drops expired entries. Entries are ordered by insertion and
+// share one TTL, so it stops at the first entry that is still live.
+func (c *Cache[K, V]) pruneLocked(now time.Time) {
+	for el := c.order.Front(); el != nil; el = c.order.Front() {
+		if !c.expired(el.Value.(*entry[K, V]), now) {
+			return
+		}
+
+		c.removeLocked(el)
+	}
+}
+
+func (c *Cache[K, V]) removeLocked(el *list.Element) {
+	e := c.order.Remove(el).(*entry[K, V])
+	delete(c.items, e.key)
+}
+
+func (c *Cache[K, V]) expired(e *entry[K, V], now time.Time) bool {
+	return c.ttl > 0 && !now.Before(e.expiresAt)
+}
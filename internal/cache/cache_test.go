@@ -0,0 +1,261 @@
+package cache
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (f *fakeClock) now() time.Time {
+	return f.t
+}
+
+func (f *fakeClock) advance(d time.Duration) {
+	f.t = f.t.Add(d)
+}
+
+func newWithClock[K comparable, V any](ttl time.Duration, maxEntries int) (*Cache[K, V], *fakeClock) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	c := New[K, V](ttl, maxEntries)
+	c.now = clock.now
+
+	return c, clock
+}
+
+func TestGetHit(t *testing.T) {
+	c := New[string, int](time.Minute, 0)
+	c.Set("a", 1)
+
+	v, ok := c.Get("a")
+	if !ok || v != 1 {
+		t.Fatalf("Get(a) = %v, %v; want 1, true", v, ok)
+	}
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatalf("Get(b) hit on a missing key")
+	}
+
+	s := c.Stats()
+	if s.Hits != 1 || s.Misses != 1 || s.Size != 1 {
+		t.Fatalf("Stats() = %+v; want 1 hit, 1 miss, size 1", s)
+	}
+}
+
+func TestGetMissAfterExpiry(t *testing.T) {
+	c, clock := newWithClock[string, int](time.Minute, 0)
+	c.Set("a", 1)
+
+	clock.advance(59 * time.Second)
+
+	if _, ok := c.Get("a"); !ok {
+		t.Fatalf("Get(a) missed before the TTL expired")
+	}
+
+	clock.advance(time.Second)
+
+	if _, ok := c.Get("a"); ok {
+		t.Fatalf("Get(a) hit after the TTL expired")
+	}
+}
+
+func TestUnboundedPrunesExpired(t *testing.T) {
+	c, clock := newWithClock[string, int](time.Minute, 0)
+	c.Set("a", 1)
+	c.Set("b", 2)
+
+	clock.advance(time.Minute)
+
+	c.Set("c", 3)
+
+	if s := c.Stats(); s.Size != 1 {
+		t.Fatalf("Stats().Size = %d; want 1 after expired entries are pruned", s.Size)
+	}
+}
+
+func TestNoExpiry(t *testing.T) {
+	c, clock := newWithClock[string, int](0, 0)
+	c.Set("a", 1)
+
+	clock.advance(24 * time.Hour)
+
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("Get(a) = %v, %v; want 1, true with no TTL", v, ok)
+	}
+}
+
+func TestEvictionAtMaxEntries(t *testing.T) {
+	c := New[string, int](time.Minute, 2)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+
+	if _, ok := c.Get("a"); ok {
+		t.Fatalf("Get(a) hit; want the oldest entry evicted")
+	}
+
+	for _, k := range []string{"b", "c"} {
+		if _, ok := c.Get(k); !ok {
+			t.Fatalf("Get(%s) missed; want it kept", k)
+		}
+	}
+
+	s := c.Stats()
+	if s.Evictions != 1 || s.Size != 2 {
+		t.Fatalf("Stats() = %+v; want 1 eviction, size 2", s)
+	}
+}
+
+func TestSetExistingKeyDoesNotEvict(t *testing.T) {
+	c := New[string, int](time.Minute, 2)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("a", 3)
+
+	if v, _ := c.Get("a"); v != 3 {
+		t.Fatalf("Get(a) = %v; want 3", v)
+	}
+
+	if s := c.Stats(); s.Evictions != 0 || s.Size != 2 {
+		t.Fatalf("Stats() = %+v; want no evictions, size 2", s)
+	}
+}
+
+func TestGetOrLoadDoesNotCacheErrors(t *testing.T) {
+	c := New[string, int](time.Minute, 0)
+	errLoad := errors.New("load failed")
+	calls := 0
+
+	load := func() (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, errLoad
+		}
+		return 42, nil
+	}
+
+	if _, err := c.GetOrLoad("a", load); !errors.Is(err, errLoad) {
+		t.Fatalf("GetOrLoad error = %v; want %v", err, errLoad)
+	}
+
+	v, err := c.GetOrLoad("a", load)
+	if err != nil || v != 42 {
+		t.Fatalf("GetOrLoad = %v, %v; want 42, nil", v, err)
+	}
+
+	if _, err := c.GetOrLoad("a", load); err != nil || calls != 2 {
+		t.Fatalf("GetOrLoad error = %v, load called %d times; want nil, 2", err, calls)
+	}
+}
+
+func TestGetOrLoadSharesInflightLoad(t *testing.T) {
+	c := New[string, int](time.Minute, 0)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var calls atomic.Int32
+
+	load := func() (int, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		return 7, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := c.GetOrLoad("a", load); err != nil || v != 7 {
+				t.Errorf("GetOrLoad = %v, %v; want 7, nil", v, err)
+			}
+		}()
+	}
+
+	<-started
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("load called %d times; want 1", n)
+	}
+}
+
+func TestInvalidateDuringLoad(t *testing.T) {
+	tests := []struct {
+		name       string
+		invalidate func(c *Cache[string, int])
+	}{
+		{"Delete", func(c *Cache[string, int]) { c.Delete("a") }},
+		{"Clear", func(c *Cache[string, int]) { c.Clear() }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New[string, int](time.Minute, 0)
+			started := make(chan struct{})
+			release := make(chan struct{})
+			done := make(chan struct{})
+
+			go func() {
+				defer close(done)
+				v, err := c.GetOrLoad("a", func() (int, error) {
+					close(started)
+					<-release
+					return 1, nil
+				})
+				if err != nil || v != 1 {
+					t.Errorf("GetOrLoad = %v, %v; want 1, nil", v, err)
+				}
+			}()
+
+			<-started
+			tt.invalidate(c)
+			close(release)
+			<-done
+
+			if v, ok := c.Get("a"); ok {
+				t.Fatalf("Get(a) = %v after %s during load; want a miss", v, tt.name)
+			}
+
+			v, err := c.GetOrLoad("a", func() (int, error) { return 2, nil })
+			if err != nil || v != 2 {
+				t.Fatalf("GetOrLoad = %v, %v; want a fresh load of 2, nil", v, err)
+			}
+		})
+	}
+}
+
+func TestConcurrentGetSet(t *testing.T) {
+	c := New[string, int](time.Minute, 50)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				k := strconv.Itoa((i * j) % 100)
+				c.Set(k, j)
+				c.Get(k)
+				if j%100 == 0 {
+					c.Delete(k)
+					c.Stats()
+				}
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if s := c.Stats(); s.Size > 50 {
+		t.Fatalf("Stats().Size = %d; want at most 50", s.Size)
+	}
+}